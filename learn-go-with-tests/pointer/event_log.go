@@ -0,0 +1,180 @@
+package main
+
+import "time"
+
+// EventKind identifies what a Wallet Event represents.
+type EventKind int
+
+const (
+	Deposited EventKind = iota
+	Withdrawn
+)
+
+// Event is a single balance-changing occurrence recorded in a Wallet's
+// history.
+type Event struct {
+	Kind     EventKind
+	Currency Currency
+	Amount   float64
+	At       time.Time
+}
+
+// BackpressureMode controls what a Wallet does when its internal event log
+// is full and a new Event needs recording.
+type BackpressureMode int
+
+const (
+	// Block makes Deposit/Withdraw wait for the log's background consumer
+	// to make room.
+	Block BackpressureMode = iota
+	// DropOldest discards the oldest buffered event to make room, so
+	// Deposit/Withdraw never wait on a slow log consumer.
+	DropOldest
+)
+
+// WalletConfig configures the capacity and back-pressure behavior of a
+// Wallet's event log.
+type WalletConfig struct {
+	// EventBufferSize is the capacity of the wallet's internal event log
+	// and of each channel returned by Subscribe.
+	EventBufferSize int
+	Backpressure    BackpressureMode
+}
+
+// DefaultWalletConfig is used by NewWallet.
+var DefaultWalletConfig = WalletConfig{
+	EventBufferSize: 256,
+	Backpressure:    Block,
+}
+
+// record enqueues e onto the event log for the background consumer to fan
+// out to subscribers. The caller is responsible for appending e to
+// w.history itself, in the same critical section that mutated the balance,
+// so that history order always matches mutation order.
+func (w *Wallet) record(e Event) {
+	select {
+	case w.log <- e:
+		return
+	default:
+	}
+
+	switch w.cfg.Backpressure {
+	case DropOldest:
+		select {
+		case <-w.log:
+		default:
+		}
+		select {
+		case w.log <- e:
+		default:
+			// Another writer refilled the log first; e itself is dropped.
+		}
+	default: // Block
+		select {
+		case w.log <- e:
+		case <-w.stop:
+		}
+	}
+}
+
+// runLog is the wallet's background consumer: it takes each event off the
+// log (the balance itself is already updated synchronously by
+// Deposit/Withdraw) and fans it out to every current subscriber.
+func (w *Wallet) runLog() {
+	defer close(w.done)
+	for {
+		select {
+		case e := <-w.log:
+			w.fanOut(e)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+func (w *Wallet) fanOut(e Event) {
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	for _, sub := range w.subscribers {
+		select {
+		case sub <- e:
+		default:
+			// Slow subscriber: drop rather than block the log consumer.
+		}
+	}
+}
+
+// Subscribe registers a new downstream consumer and returns a channel that
+// receives every Event recorded from now on. The channel is buffered per
+// the wallet's WalletConfig.EventBufferSize; a subscriber that falls behind
+// misses events rather than blocking the wallet. Under DropOldest
+// back-pressure, events discarded from a full log reach no subscriber.
+func (w *Wallet) Subscribe() <-chan Event {
+	ch := make(chan Event, w.cfg.EventBufferSize)
+	w.subMu.Lock()
+	w.subscribers = append(w.subscribers, ch)
+	w.subMu.Unlock()
+	return ch
+}
+
+// History returns a copy of every Event the wallet has recorded so far.
+func (w *Wallet) History() []Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	history := make([]Event, len(w.history))
+	copy(history, w.history)
+	return history
+}
+
+// Snapshot is an instantaneous, consistent read of every balance a Wallet
+// holds.
+type Snapshot struct {
+	Balances map[Currency]Money
+	At       time.Time
+}
+
+// Snapshot returns an instantaneous, consistent read of every balance the
+// wallet currently holds.
+func (w *Wallet) Snapshot() Snapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	balances := make(map[Currency]Money, len(w.balances))
+	for currency, amount := range w.balances {
+		balances[currency], _ = moneyOf(currency, amount)
+	}
+	return Snapshot{Balances: balances, At: time.Now()}
+}
+
+// Close stops the wallet's background log consumer. It does not close any
+// channels returned by Subscribe, since callers may still be draining them.
+func (w *Wallet) Close() {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+	})
+}
+
+// Replay rebuilds a Wallet's balances by folding a sequence of historical
+// events in order, as if they had been applied via Deposit/Withdraw.
+// Events that would withdraw more than the running balance are ignored,
+// mirroring the ErrInsufficientFunds guard in Withdraw.
+func Replay(events []Event, rates RateProvider) *Wallet {
+	w := NewWallet(rates)
+	for _, e := range events {
+		switch e.Kind {
+		case Deposited:
+			m, err := moneyOf(e.Currency, e.Amount)
+			if err != nil {
+				continue
+			}
+			w.Deposit(m)
+		case Withdrawn:
+			m, err := moneyOf(e.Currency, e.Amount)
+			if err != nil {
+				continue
+			}
+			w.Withdraw(m)
+		}
+	}
+	return w
+}
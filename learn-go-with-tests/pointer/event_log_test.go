@@ -0,0 +1,120 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWalletRecordsHistoryAndReplaysIt(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+
+	wallet.Deposit(Bitcoin(10))
+	wallet.Deposit(Bitcoin(5))
+	wallet.Withdraw(Bitcoin(3))
+
+	history := wallet.History()
+	if len(history) != 3 {
+		t.Fatalf("got %d events, want 3", len(history))
+	}
+	if history[0].Kind != Deposited || history[2].Kind != Withdrawn {
+		t.Errorf("unexpected event kinds: %+v", history)
+	}
+
+	replayed := Replay(history, nil)
+	defer replayed.Close()
+
+	got, err := replayed.Balance(CurrencyBTC)
+	if err != nil {
+		t.Fatalf("balance: unexpected error: %v", err)
+	}
+	if want := Bitcoin(12); got != want {
+		t.Errorf("got replayed balance %s, want %s", got, want)
+	}
+}
+
+func TestWalletSubscribeReceivesEvents(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+
+	events := wallet.Subscribe()
+
+	wallet.Deposit(Bitcoin(7))
+
+	select {
+	case e := <-events:
+		if e.Kind != Deposited || e.Currency != CurrencyBTC || e.Amount != 7 {
+			t.Errorf("got unexpected event %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for subscribed event")
+	}
+}
+
+func TestWalletConcurrentDepositWithdrawHasNoRaces(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+
+	const goroutines = 50
+	const opsEach = 40
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < opsEach; j++ {
+				wallet.Deposit(Bitcoin(2))
+				_ = wallet.Withdraw(Bitcoin(1))
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := wallet.Balance(CurrencyBTC)
+	if err != nil {
+		t.Fatalf("balance: unexpected error: %v", err)
+	}
+	if want := Bitcoin(goroutines * opsEach); got != want {
+		t.Errorf("got balance %s, want %s", got, want)
+	}
+
+	replayed := Replay(wallet.History(), nil)
+	defer replayed.Close()
+
+	replayedGot, err := replayed.Balance(CurrencyBTC)
+	if err != nil {
+		t.Fatalf("replayed balance: unexpected error: %v", err)
+	}
+	if replayedGot != got {
+		t.Errorf("replaying recorded history gave balance %s, want it to match live balance %s", replayedGot, got)
+	}
+}
+
+func TestWalletDropOldestBackpressureNeverBlocksCallers(t *testing.T) {
+	wallet := NewWalletWithConfig(nil, WalletConfig{EventBufferSize: 1, Backpressure: DropOldest})
+	defer wallet.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 1000; i++ {
+			wallet.Deposit(Bitcoin(1))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("DropOldest wallet blocked callers under a full event log")
+	}
+
+	got, err := wallet.Balance(CurrencyBTC)
+	if err != nil {
+		t.Fatalf("balance: unexpected error: %v", err)
+	}
+	if want := Bitcoin(1000); got != want {
+		t.Errorf("got balance %s, want %s", got, want)
+	}
+}
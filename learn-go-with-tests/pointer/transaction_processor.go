@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRequestTimeout is returned by Process when a request isn't handled
+// within the processor's configured timeout.
+var ErrRequestTimeout = errors.New("transaction processor: request timed out")
+
+// ErrProcessorStopped is returned by Process once the processor has been
+// shut down.
+var ErrProcessorStopped = errors.New("transaction processor: stopped")
+
+// TxKind identifies whether a TxRequest deposits or withdraws funds.
+type TxKind int
+
+const (
+	TxDeposit TxKind = iota
+	TxWithdraw
+)
+
+// TxRequest is a deposit or withdraw submitted to a TransactionProcessor.
+// Reply receives exactly one TxResult once the request has been handled.
+type TxRequest struct {
+	Kind  TxKind
+	Money Money
+	Reply chan<- TxResult
+}
+
+// TxResult is the outcome of processing a TxRequest.
+type TxResult struct {
+	Err error
+}
+
+// Metrics is a point-in-time read of a TransactionProcessor's counters.
+type Metrics struct {
+	Processed         int64
+	Timeouts          int64
+	InsufficientFunds int64
+}
+
+// TransactionProcessor serializes deposit/withdraw requests against a
+// Wallet on a single goroutine, so callers never need to synchronize
+// access to the wallet themselves.
+type TransactionProcessor struct {
+	wallet     *Wallet
+	requests   chan TxRequest
+	snapshots  chan Snapshot
+	quit       chan struct{}
+	done       chan struct{}
+	reqTimeout time.Duration
+	snapPeriod time.Duration
+
+	processed         int64
+	timeouts          int64
+	insufficientFunds int64
+
+	// testDelay, when non-zero, artificially slows the handling of every
+	// request. It exists so tests can exercise the per-request timeout
+	// deterministically instead of racing real wall-clock time.
+	testDelay time.Duration
+}
+
+// NewTransactionProcessor starts a TransactionProcessor over wallet.
+// reqTimeout bounds how long Process will wait for a request to be handled
+// before reporting ErrRequestTimeout; snapPeriod controls how often a
+// balance Snapshot is published on Snapshots().
+func NewTransactionProcessor(wallet *Wallet, reqTimeout, snapPeriod time.Duration) *TransactionProcessor {
+	p := &TransactionProcessor{
+		wallet:     wallet,
+		requests:   make(chan TxRequest),
+		snapshots:  make(chan Snapshot, 1),
+		quit:       make(chan struct{}),
+		done:       make(chan struct{}),
+		reqTimeout: reqTimeout,
+		snapPeriod: snapPeriod,
+	}
+	go p.run()
+	return p
+}
+
+// Snapshots returns the channel periodic balance snapshots are published on.
+// It is buffered with capacity 1; while a snapshot nobody has read yet is
+// buffered, newer snapshots are dropped rather than blocking request
+// processing.
+func (p *TransactionProcessor) Snapshots() <-chan Snapshot {
+	return p.snapshots
+}
+
+func (p *TransactionProcessor) run() {
+	defer close(p.done)
+
+	tick := time.NewTicker(p.snapPeriod)
+	defer tick.Stop()
+
+	for {
+		select {
+		case req := <-p.requests:
+			p.handle(req)
+		case now := <-tick.C:
+			p.publishSnapshot(now)
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+func (p *TransactionProcessor) handle(req TxRequest) {
+	if p.testDelay > 0 {
+		time.Sleep(p.testDelay)
+	}
+
+	var err error
+	switch req.Kind {
+	case TxDeposit:
+		p.wallet.Deposit(req.Money)
+	case TxWithdraw:
+		if err = p.wallet.Withdraw(req.Money); err == ErrInsufficientFunds {
+			atomic.AddInt64(&p.insufficientFunds, 1)
+		}
+	}
+
+	atomic.AddInt64(&p.processed, 1)
+	req.Reply <- TxResult{Err: err}
+}
+
+func (p *TransactionProcessor) publishSnapshot(at time.Time) {
+	snap := p.wallet.Snapshot()
+	snap.At = at
+
+	select {
+	case p.snapshots <- snap:
+	default:
+		// Nobody's listening; drop it rather than block request processing.
+	}
+}
+
+// Process submits a deposit or withdraw request and waits up to the
+// processor's configured timeout for it to be handled.
+func (p *TransactionProcessor) Process(kind TxKind, m Money) error {
+	reply := make(chan TxResult, 1)
+	req := TxRequest{Kind: kind, Money: m, Reply: reply}
+	timeout := time.After(p.reqTimeout)
+
+	select {
+	case p.requests <- req:
+	case <-p.quit:
+		return ErrProcessorStopped
+	case <-timeout:
+		atomic.AddInt64(&p.timeouts, 1)
+		return ErrRequestTimeout
+	}
+
+	select {
+	case res := <-reply:
+		return res.Err
+	case <-timeout:
+		atomic.AddInt64(&p.timeouts, 1)
+		return ErrRequestTimeout
+	}
+}
+
+// MetricsSnapshot returns a point-in-time read of the processor's counters.
+func (p *TransactionProcessor) MetricsSnapshot() Metrics {
+	return Metrics{
+		Processed:         atomic.LoadInt64(&p.processed),
+		Timeouts:          atomic.LoadInt64(&p.timeouts),
+		InsufficientFunds: atomic.LoadInt64(&p.insufficientFunds),
+	}
+}
+
+// Shutdown stops the processor's goroutine and waits for it to exit.
+func (p *TransactionProcessor) Shutdown() {
+	close(p.quit)
+	<-p.done
+}
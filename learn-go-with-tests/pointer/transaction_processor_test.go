@@ -0,0 +1,134 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransactionProcessorDepositAndWithdraw(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+	p := NewTransactionProcessor(wallet, time.Second, time.Hour)
+	defer p.Shutdown()
+
+	if err := p.Process(TxDeposit, Bitcoin(10)); err != nil {
+		t.Fatalf("deposit: unexpected error: %v", err)
+	}
+	if err := p.Process(TxWithdraw, Bitcoin(4)); err != nil {
+		t.Fatalf("withdraw: unexpected error: %v", err)
+	}
+
+	got, err := p.wallet.Balance(CurrencyBTC)
+	if err != nil {
+		t.Fatalf("balance: unexpected error: %v", err)
+	}
+	if want := Bitcoin(6); got != want {
+		t.Errorf("got balance %s want %s", got, want)
+	}
+
+	metrics := p.MetricsSnapshot()
+	if metrics.Processed != 2 {
+		t.Errorf("got %d processed, want 2", metrics.Processed)
+	}
+}
+
+func TestTransactionProcessorInsufficientFunds(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+	p := NewTransactionProcessor(wallet, time.Second, time.Hour)
+	defer p.Shutdown()
+
+	err := p.Process(TxWithdraw, Bitcoin(100))
+	if err != ErrInsufficientFunds {
+		t.Fatalf("got error %v, want %v", err, ErrInsufficientFunds)
+	}
+
+	if got := p.MetricsSnapshot().InsufficientFunds; got != 1 {
+		t.Errorf("got %d insufficient-funds rejections, want 1", got)
+	}
+}
+
+func TestTransactionProcessorTimesOutOnSlowHandling(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+	p := NewTransactionProcessor(wallet, time.Millisecond, time.Hour)
+	p.testDelay = 50 * time.Millisecond
+	defer p.Shutdown()
+
+	err := p.Process(TxDeposit, Bitcoin(1))
+	if err != ErrRequestTimeout {
+		t.Fatalf("got error %v, want %v", err, ErrRequestTimeout)
+	}
+
+	if got := p.MetricsSnapshot().Timeouts; got != 1 {
+		t.Errorf("got %d timeouts, want 1", got)
+	}
+}
+
+func TestTransactionProcessorConcurrentLoadNeverDeadlocks(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+	p := NewTransactionProcessor(wallet, time.Second, time.Millisecond)
+	defer p.Shutdown()
+
+	const goroutines = 50
+	const depositsEach = 20
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < depositsEach; j++ {
+				if err := p.Process(TxDeposit, Bitcoin(1)); err != nil {
+					t.Errorf("deposit: unexpected error: %v", err)
+				}
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock: concurrent deposits did not complete in time")
+	}
+
+	got, err := p.wallet.Balance(CurrencyBTC)
+	if err != nil {
+		t.Fatalf("balance: unexpected error: %v", err)
+	}
+	if want := Bitcoin(goroutines * depositsEach); got != want {
+		t.Errorf("got balance %s want %s", got, want)
+	}
+
+	if got := p.MetricsSnapshot().Processed; got != goroutines*depositsEach {
+		t.Errorf("got %d processed, want %d", got, goroutines*depositsEach)
+	}
+}
+
+func TestTransactionProcessorPublishesSnapshots(t *testing.T) {
+	wallet := NewWallet(nil)
+	defer wallet.Close()
+	p := NewTransactionProcessor(wallet, time.Second, 5*time.Millisecond)
+	defer p.Shutdown()
+
+	if err := p.Process(TxDeposit, Bitcoin(3)); err != nil {
+		t.Fatalf("deposit: unexpected error: %v", err)
+	}
+
+	select {
+	case snap := <-p.Snapshots():
+		if got := snap.Balances[CurrencyBTC]; got != Bitcoin(3) {
+			t.Errorf("got snapshot balance %s want %s", got, Bitcoin(3))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a balance snapshot")
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Currency identifies the denomination of a Money value.
+type Currency string
+
+const (
+	CurrencyBTC Currency = "BTC"
+	CurrencyETH Currency = "ETH"
+	CurrencyUSD Currency = "USD"
+)
+
+// Money is an amount denominated in a particular Currency.
+type Money interface {
+	fmt.Stringer
+	Currency() Currency
+	Amount() float64
+}
+
+// Bitcoin is an amount of BTC.
+type Bitcoin int
+
+func (b Bitcoin) String() string     { return fmt.Sprintf("%d BTC", int(b)) }
+func (b Bitcoin) Currency() Currency { return CurrencyBTC }
+func (b Bitcoin) Amount() float64    { return float64(b) }
+
+// Ethereum is an amount of ETH.
+type Ethereum float64
+
+func (e Ethereum) String() string     { return fmt.Sprintf("%.4f ETH", float64(e)) }
+func (e Ethereum) Currency() Currency { return CurrencyETH }
+func (e Ethereum) Amount() float64    { return float64(e) }
+
+// USD is an amount of US dollars.
+type USD float64
+
+func (u USD) String() string     { return fmt.Sprintf("$%.2f", float64(u)) }
+func (u USD) Currency() Currency { return CurrencyUSD }
+func (u USD) Amount() float64    { return float64(u) }
+
+// ErrInsufficientFunds is returned when a Withdraw would take a currency's
+// balance below zero.
+var ErrInsufficientFunds = errors.New("cannot withdraw, insufficient funds")
+
+// ErrUnsupportedCurrency is returned when a Currency isn't known to the
+// wallet or its RateProvider.
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// moneyOf builds the Money value for a currency/amount pair.
+func moneyOf(currency Currency, amount float64) (Money, error) {
+	switch currency {
+	case CurrencyBTC:
+		return Bitcoin(amount), nil
+	case CurrencyETH:
+		return Ethereum(amount), nil
+	case CurrencyUSD:
+		return USD(amount), nil
+	default:
+		return nil, ErrUnsupportedCurrency
+	}
+}
+
+// RateProvider converts an amount of one currency into another.
+type RateProvider interface {
+	// Rate returns how many units of `to` one unit of `from` is worth.
+	Rate(from, to Currency) (float64, error)
+}
+
+// InMemoryRateProvider is a RateProvider backed by a fixed table of rates,
+// useful for tests and offline use.
+type InMemoryRateProvider struct {
+	rates map[Currency]map[Currency]float64
+}
+
+// NewInMemoryRateProvider builds an InMemoryRateProvider from a table of
+// rates, keyed by source then destination currency.
+func NewInMemoryRateProvider(rates map[Currency]map[Currency]float64) *InMemoryRateProvider {
+	return &InMemoryRateProvider{rates: rates}
+}
+
+func (p *InMemoryRateProvider) Rate(from, to Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	byDest, ok := p.rates[from]
+	if !ok {
+		return 0, ErrUnsupportedCurrency
+	}
+	rate, ok := byDest[to]
+	if !ok {
+		return 0, ErrUnsupportedCurrency
+	}
+	return rate, nil
+}
+
+// HTTPRateProvider is a RateProvider backed by a remote exchange-rate API.
+// It expects GET {BaseURL}?from=X&to=Y to respond with {"rate": <float64>}.
+type HTTPRateProvider struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+// NewHTTPRateProvider builds an HTTPRateProvider, defaulting to
+// http.DefaultClient when none is supplied.
+func NewHTTPRateProvider(baseURL string, client *http.Client) *HTTPRateProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPRateProvider{BaseURL: baseURL, Client: client}
+}
+
+func (p *HTTPRateProvider) Rate(from, to Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+
+	url := fmt.Sprintf("%s?from=%s&to=%s", p.BaseURL, from, to)
+	resp, err := p.Client.Get(url)
+	if err != nil {
+		return 0, fmt.Errorf("fetching rate: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("fetching rate: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Rate float64 `json:"rate"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("decoding rate response: %w", err)
+	}
+
+	return body.Rate, nil
+}
+
+// Wallet holds balances across multiple currencies, converting between them
+// via a RateProvider. Every Deposit/Withdraw is recorded as an Event in an
+// append-only history and published to downstream Subscribe()rs; see
+// event_log.go.
+type Wallet struct {
+	mu       sync.Mutex
+	balances map[Currency]float64
+	history  []Event
+	rates    RateProvider
+
+	cfg WalletConfig
+	log chan Event
+
+	subMu       sync.Mutex
+	subscribers []chan Event
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewWallet creates an empty Wallet that converts currencies using rates,
+// with the DefaultWalletConfig event log.
+func NewWallet(rates RateProvider) *Wallet {
+	return NewWalletWithConfig(rates, DefaultWalletConfig)
+}
+
+// NewWalletWithConfig creates an empty Wallet whose event log is sized and
+// behaves under back-pressure as described by cfg.
+func NewWalletWithConfig(rates RateProvider, cfg WalletConfig) *Wallet {
+	if cfg.EventBufferSize <= 0 {
+		cfg.EventBufferSize = DefaultWalletConfig.EventBufferSize
+	}
+
+	w := &Wallet{
+		balances: make(map[Currency]float64),
+		rates:    rates,
+		cfg:      cfg,
+		log:      make(chan Event, cfg.EventBufferSize),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.runLog()
+	return w
+}
+
+// Deposit adds m to the balance of its currency and records a Deposited
+// event.
+func (w *Wallet) Deposit(m Money) {
+	e := Event{Kind: Deposited, Currency: m.Currency(), Amount: m.Amount(), At: time.Now()}
+
+	w.mu.Lock()
+	w.balances[m.Currency()] += m.Amount()
+	w.history = append(w.history, e)
+	w.mu.Unlock()
+
+	w.record(e)
+}
+
+// Withdraw removes m from the balance of its currency, failing with
+// ErrInsufficientFunds if that would take the balance below zero. On
+// success it records a Withdrawn event.
+func (w *Wallet) Withdraw(m Money) error {
+	e := Event{Kind: Withdrawn, Currency: m.Currency(), Amount: m.Amount(), At: time.Now()}
+
+	w.mu.Lock()
+	if w.balances[m.Currency()] < m.Amount() {
+		w.mu.Unlock()
+		return ErrInsufficientFunds
+	}
+	w.balances[m.Currency()] -= m.Amount()
+	w.history = append(w.history, e)
+	w.mu.Unlock()
+
+	w.record(e)
+	return nil
+}
+
+// Balance returns the wallet's balance held directly in currency.
+func (w *Wallet) Balance(currency Currency) (Money, error) {
+	w.mu.Lock()
+	amount := w.balances[currency]
+	w.mu.Unlock()
+	return moneyOf(currency, amount)
+}
+
+// BalanceIn converts every currency the wallet holds into currency and
+// returns the total, using the wallet's RateProvider.
+func (w *Wallet) BalanceIn(currency Currency) (Money, error) {
+	w.mu.Lock()
+	balances := make(map[Currency]float64, len(w.balances))
+	for c, amount := range w.balances {
+		balances[c] = amount
+	}
+	w.mu.Unlock()
+
+	var total float64
+	for from, amount := range balances {
+		if amount == 0 {
+			continue
+		}
+		rate, err := w.rates.Rate(from, currency)
+		if err != nil {
+			return nil, err
+		}
+		total += amount * rate
+	}
+	return moneyOf(currency, total)
+}
@@ -1,12 +1,20 @@
 package main
 
-import "testing"
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestWallet(t *testing.T) {
 
-	assertBalance := func(t *testing.T, wallet Wallet, want Bitcoin) {
+	assertBalance := func(t *testing.T, wallet *Wallet, currency Currency, want Money) {
 		t.Helper()
-		got := wallet.Balance()
+		got, err := wallet.Balance(currency)
+		if err != nil {
+			t.Fatalf("didn't want an error but got one: %v", err)
+		}
 
 		if got != want {
 			t.Errorf("got %s want %s", got, want)
@@ -25,25 +33,178 @@ func TestWallet(t *testing.T) {
 	}
 
 	t.Run("Deposit", func(t *testing.T) {
-		wallet := Wallet{}
+		wallet := NewWallet(nil)
+		defer wallet.Close()
 		wallet.Deposit(Bitcoin(10))
-		assertBalance(t, wallet, Bitcoin(10))
+		assertBalance(t, wallet, CurrencyBTC, Bitcoin(10))
 	})
 
 	t.Run("Withdraw", func(t *testing.T) {
-		wallet := Wallet{money: Bitcoin(20)}
+		wallet := NewWallet(nil)
+		defer wallet.Close()
+		wallet.Deposit(Bitcoin(20))
 		wallet.Withdraw(Bitcoin(10))
-		assertBalance(t, wallet, Bitcoin(10))
+		assertBalance(t, wallet, CurrencyBTC, Bitcoin(10))
 	})
 
 	t.Run("Withdraw insufficient funds", func(t *testing.T) {
-		startingBalance := Bitcoin(20)
-		wallet := Wallet{startingBalance}
+		wallet := NewWallet(nil)
+		defer wallet.Close()
+		wallet.Deposit(Bitcoin(20))
 		err := wallet.Withdraw(Bitcoin(100))
 
-		assertBalance(t, wallet, startingBalance)
+		assertBalance(t, wallet, CurrencyBTC, Bitcoin(20))
 		assertError(t, err, ErrInsufficientFunds)
+	})
+
+	t.Run("Balance of a currency never deposited is zero", func(t *testing.T) {
+		wallet := NewWallet(nil)
+		defer wallet.Close()
+		assertBalance(t, wallet, CurrencyUSD, USD(0))
+	})
+
+	t.Run("Balance of an unsupported currency errors", func(t *testing.T) {
+		wallet := NewWallet(nil)
+		defer wallet.Close()
+		_, err := wallet.Balance(Currency("JPY"))
+		assertError(t, err, ErrUnsupportedCurrency)
+	})
+
+	t.Run("tracks multiple currencies independently", func(t *testing.T) {
+		wallet := NewWallet(nil)
+		defer wallet.Close()
+		wallet.Deposit(Bitcoin(10))
+		wallet.Deposit(USD(50))
+		wallet.Deposit(Ethereum(2))
 
+		assertBalance(t, wallet, CurrencyBTC, Bitcoin(10))
+		assertBalance(t, wallet, CurrencyUSD, USD(50))
+		assertBalance(t, wallet, CurrencyETH, Ethereum(2))
 	})
 
+	t.Run("BalanceIn converts every currency held using the RateProvider", func(t *testing.T) {
+		rates := NewInMemoryRateProvider(map[Currency]map[Currency]float64{
+			CurrencyBTC: {CurrencyUSD: 50000},
+			CurrencyETH: {CurrencyUSD: 3000},
+		})
+		wallet := NewWallet(rates)
+		defer wallet.Close()
+		wallet.Deposit(Bitcoin(1))
+		wallet.Deposit(Ethereum(2))
+		wallet.Deposit(USD(100))
+
+		got, err := wallet.BalanceIn(CurrencyUSD)
+		if err != nil {
+			t.Fatalf("didn't want an error but got one: %v", err)
+		}
+
+		want := USD(50000 + 2*3000 + 100)
+		if got != want {
+			t.Errorf("got %s want %s", got, want)
+		}
+	})
+
+	t.Run("BalanceIn a currency the RateProvider doesn't know errors", func(t *testing.T) {
+		rates := NewInMemoryRateProvider(map[Currency]map[Currency]float64{})
+		wallet := NewWallet(rates)
+		defer wallet.Close()
+		wallet.Deposit(Bitcoin(1))
+
+		_, err := wallet.BalanceIn(CurrencyUSD)
+		assertError(t, err, ErrUnsupportedCurrency)
+	})
+}
+
+func TestInMemoryRateProvider(t *testing.T) {
+	rates := NewInMemoryRateProvider(map[Currency]map[Currency]float64{
+		CurrencyBTC: {CurrencyUSD: 50000},
+	})
+
+	t.Run("same currency is always rate 1", func(t *testing.T) {
+		rate, err := rates.Rate(CurrencyBTC, CurrencyBTC)
+		if err != nil {
+			t.Fatalf("didn't want an error but got one: %v", err)
+		}
+		if rate != 1 {
+			t.Errorf("got %v want %v", rate, 1)
+		}
+	})
+
+	t.Run("known pair", func(t *testing.T) {
+		rate, err := rates.Rate(CurrencyBTC, CurrencyUSD)
+		if err != nil {
+			t.Fatalf("didn't want an error but got one: %v", err)
+		}
+		if rate != 50000 {
+			t.Errorf("got %v want %v", rate, 50000)
+		}
+	})
+
+	t.Run("unknown pair errors", func(t *testing.T) {
+		_, err := rates.Rate(CurrencyUSD, CurrencyETH)
+		if err == nil {
+			t.Fatal("didn't get an error but wanted one")
+		}
+	})
+}
+
+func TestHTTPRateProvider(t *testing.T) {
+	t.Run("decodes a successful response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.URL.Query().Get("from"), "BTC"; got != want {
+				t.Errorf("got from=%q, want %q", got, want)
+			}
+			if got, want := r.URL.Query().Get("to"), "USD"; got != want {
+				t.Errorf("got to=%q, want %q", got, want)
+			}
+			json.NewEncoder(w).Encode(map[string]float64{"rate": 50000})
+		}))
+		defer server.Close()
+
+		provider := NewHTTPRateProvider(server.URL, nil)
+		rate, err := provider.Rate(CurrencyBTC, CurrencyUSD)
+		if err != nil {
+			t.Fatalf("didn't want an error but got one: %v", err)
+		}
+		if rate != 50000 {
+			t.Errorf("got %v want %v", rate, 50000)
+		}
+	})
+
+	t.Run("same currency is always rate 1 without a request", func(t *testing.T) {
+		provider := NewHTTPRateProvider("http://unused.invalid", nil)
+		rate, err := provider.Rate(CurrencyBTC, CurrencyBTC)
+		if err != nil {
+			t.Fatalf("didn't want an error but got one: %v", err)
+		}
+		if rate != 1 {
+			t.Errorf("got %v want %v", rate, 1)
+		}
+	})
+
+	t.Run("non-200 status errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		provider := NewHTTPRateProvider(server.URL, nil)
+		_, err := provider.Rate(CurrencyBTC, CurrencyUSD)
+		if err == nil {
+			t.Fatal("didn't get an error but wanted one")
+		}
+	})
+
+	t.Run("malformed body errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("not json"))
+		}))
+		defer server.Close()
+
+		provider := NewHTTPRateProvider(server.URL, nil)
+		_, err := provider.Rate(CurrencyBTC, CurrencyUSD)
+		if err == nil {
+			t.Fatal("didn't get an error but wanted one")
+		}
+	})
 }
@@ -1,6 +1,11 @@
 package main
 
-import "math"
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
 
 type Shape interface {
 	Area() float64
@@ -26,3 +31,160 @@ func (c Circle) Area() float64 {
 func Perimeter(r *Rectangle) float64 {
 	return (r.Width + r.Height) * 2
 }
+
+// Solid is a three-dimensional shape.
+type Solid interface {
+	Volume() float64
+	SurfaceArea() float64
+}
+
+type Sphere struct {
+	Radius float64
+}
+
+func (s Sphere) Volume() float64 {
+	return (4.0 / 3.0) * math.Pi * s.Radius * s.Radius * s.Radius
+}
+
+func (s Sphere) SurfaceArea() float64 {
+	return 4 * math.Pi * s.Radius * s.Radius
+}
+
+type Cuboid struct {
+	Width  float64
+	Height float64
+	Depth  float64
+}
+
+func (c Cuboid) Volume() float64 {
+	return c.Width * c.Height * c.Depth
+}
+
+func (c Cuboid) SurfaceArea() float64 {
+	return 2 * (c.Width*c.Height + c.Width*c.Depth + c.Height*c.Depth)
+}
+
+type Cylinder struct {
+	Radius float64
+	Height float64
+}
+
+func (c Cylinder) Volume() float64 {
+	return math.Pi * c.Radius * c.Radius * c.Height
+}
+
+func (c Cylinder) SurfaceArea() float64 {
+	return 2*math.Pi*c.Radius*c.Radius + 2*math.Pi*c.Radius*c.Height
+}
+
+// Measurable is anything a Pipeline can gather statistics over: a 2D Shape,
+// a 3D Solid, or both.
+type Measurable interface{}
+
+// Stats is the aggregated result of running a Pipeline over a slice of
+// Measurable items.
+type Stats struct {
+	TotalArea   float64
+	TotalVolume float64
+	TypeCounts  map[string]int
+}
+
+// measurement is what a single worker reports back for one item.
+type measurement struct {
+	area     float64
+	volume   float64
+	typeName string
+}
+
+func measure(item Measurable) measurement {
+	m := measurement{typeName: fmt.Sprintf("%T", item)}
+	if s, ok := item.(Shape); ok {
+		m.area += s.Area()
+	}
+	if s, ok := item.(Solid); ok {
+		m.area += s.SurfaceArea()
+		m.volume = s.Volume()
+	}
+	return m
+}
+
+// Pipeline fans a slice of Measurable items out across a fixed number of
+// worker goroutines and aggregates the results.
+type Pipeline struct {
+	Workers int
+}
+
+// NewPipeline builds a Pipeline with the given number of workers, using a
+// single worker if workers is less than one.
+func NewPipeline(workers int) *Pipeline {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Pipeline{Workers: workers}
+}
+
+// Run measures every item across the pipeline's workers and returns the
+// aggregated Stats. If ctx is cancelled before all items are processed, Run
+// stops the workers and returns ctx.Err() alongside whatever was aggregated
+// so far.
+func (p *Pipeline) Run(ctx context.Context, items []Measurable) (Stats, error) {
+	jobs := make(chan Measurable)
+	results := make(chan measurement)
+	quit := make(chan struct{})
+
+	var workers sync.WaitGroup
+	for i := 0; i < p.Workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for {
+				select {
+				case item, ok := <-jobs:
+					if !ok {
+						return
+					}
+					res := measure(item)
+					select {
+					case results <- res:
+					case <-quit:
+						return
+					}
+				case <-quit:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, item := range items {
+			select {
+			case jobs <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	stats := Stats{TypeCounts: make(map[string]int)}
+	for {
+		select {
+		case r, ok := <-results:
+			if !ok {
+				return stats, nil
+			}
+			stats.TotalArea += r.area
+			stats.TotalVolume += r.volume
+			stats.TypeCounts[r.typeName]++
+		case <-ctx.Done():
+			close(quit)
+			return stats, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func assertFloat(t *testing.T, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("got %g want %g", got, want)
+	}
+}
+
+func TestSolidsVolumeAndSurfaceArea(t *testing.T) {
+	cases := []struct {
+		name        string
+		solid       Solid
+		wantVolume  float64
+		wantSurface float64
+	}{
+		{"Sphere", Sphere{Radius: 3}, 4.0 / 3.0 * math.Pi * 27, 4 * math.Pi * 9},
+		{"Cuboid", Cuboid{Width: 2, Height: 3, Depth: 4}, 24, 52},
+		{"Cylinder", Cylinder{Radius: 2, Height: 5}, math.Pi * 4 * 5, 2*math.Pi*4 + 2*math.Pi*2*5},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assertFloat(t, c.solid.Volume(), c.wantVolume)
+			assertFloat(t, c.solid.SurfaceArea(), c.wantSurface)
+		})
+	}
+}
+
+func TestPipelineRunAggregatesStats(t *testing.T) {
+	items := []Measurable{
+		Rectangle{Width: 3, Height: 4},
+		Circle{Radius: 2},
+		Sphere{Radius: 1},
+	}
+
+	p := NewPipeline(2)
+	stats, err := p.Run(context.Background(), items)
+	if err != nil {
+		t.Fatalf("didn't want an error but got one: %v", err)
+	}
+
+	wantArea := Rectangle{Width: 3, Height: 4}.Area() + Circle{Radius: 2}.Area() + Sphere{Radius: 1}.SurfaceArea()
+	assertFloat(t, stats.TotalArea, wantArea)
+	assertFloat(t, stats.TotalVolume, Sphere{Radius: 1}.Volume())
+
+	if got := stats.TypeCounts["main.Rectangle"]; got != 1 {
+		t.Errorf("got %d Rectangles counted, want 1", got)
+	}
+	if got := stats.TypeCounts["main.Circle"]; got != 1 {
+		t.Errorf("got %d Circles counted, want 1", got)
+	}
+	if got := stats.TypeCounts["main.Sphere"]; got != 1 {
+		t.Errorf("got %d Spheres counted, want 1", got)
+	}
+}
+
+func TestPipelineRunStopsOnContextCancellation(t *testing.T) {
+	items := make([]Measurable, 10000)
+	for i := range items {
+		items[i] = Rectangle{Width: 1, Height: 1}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	p := NewPipeline(4)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		_, err = p.Run(ctx, items)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not stop cleanly after its context was cancelled")
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+func benchmarkItems() []Measurable {
+	items := make([]Measurable, 0, 500)
+	for i := 0; i < 100; i++ {
+		items = append(items,
+			Rectangle{Width: 3, Height: 4},
+			Circle{Radius: 2},
+			Sphere{Radius: 1.5},
+			Cuboid{Width: 2, Height: 3, Depth: 4},
+			Cylinder{Radius: 1, Height: 5},
+		)
+	}
+	return items
+}
+
+func BenchmarkPipelineSequential(b *testing.B) {
+	items := benchmarkItems()
+	p := NewPipeline(1)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Run(ctx, items); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPipelineConcurrent(b *testing.B) {
+	items := benchmarkItems()
+	p := NewPipeline(8)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Run(ctx, items); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,33 +1,20 @@
+// Command select demonstrates the sequences package: it multiplexes a
+// single Fibonacci generator and prints its first ten values.
 package main
 
-import "fmt"
+import (
+	"fmt"
 
-func fibonacci(c, quit chan int) {
-	x, y := 0, 1
-	for {
-		// select ステートメントは、goroutineを複数の通信操作で待たせます。
-		// select は、複数ある case のいずれかが準備できるようになるまでブロックし、準備ができた case を実行します。
-		// もし、複数の case の準備ができている場合、 case はランダムに選択されます。
-		select {
-		// xの値をcチャネルに送信
-		case c <- x:
-			x, y = y, x+y
-		// quitチャネルを受信したら、後続の処理を実行
-		case <-quit:
-			fmt.Println("quit")
-			return
-		}
-	}
-}
+	"github.com/ryo246912/playground-go/scripts/sequences"
+)
 
 func main() {
-	c := make(chan int)
-	quit := make(chan int)
-	go func() {
-		for i := 0; i < 10; i++ {
-			fmt.Println(<-c)
-		}
-		quit <- 0
-	}()
-	fibonacci(c, quit)
+	quit := make(chan struct{})
+	events := sequences.Multiplex([]sequences.Generator{sequences.Fibonacci{}}, quit)
+
+	for i := 0; i < 10; i++ {
+		fmt.Println((<-events).Value)
+	}
+	close(quit)
+	fmt.Println("quit")
 }
@@ -0,0 +1,180 @@
+// Package sequences provides reusable, channel-based number-sequence
+// Generators and a reflect.Select-based Multiplex for fanning a dynamic
+// number of them into one channel of Events.
+package sequences
+
+import "reflect"
+
+// Event is a single value emitted by a Generator, tagged with the index of
+// the generator that produced it.
+type Event struct {
+	Source int
+	Value  int
+}
+
+// Generator publishes a sequence of ints onto out, stopping as soon as quit
+// is closed. A Generator that produces a finite sequence closes out itself
+// once it has emitted its last value.
+type Generator interface {
+	Run(out chan<- int, quit <-chan struct{})
+}
+
+// Fibonacci generates the Fibonacci sequence forever.
+type Fibonacci struct{}
+
+func (Fibonacci) Run(out chan<- int, quit <-chan struct{}) {
+	x, y := 0, 1
+	for {
+		// select ステートメントは、goroutineを複数の通信操作で待たせます。
+		// select は、複数ある case のいずれかが準備できるようになるまでブロックし、準備ができた case を実行します。
+		select {
+		case out <- x:
+			x, y = y, x+y
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Primes generates the sequence of prime numbers forever.
+type Primes struct{}
+
+func (Primes) Run(out chan<- int, quit <-chan struct{}) {
+	isPrime := func(n int) bool {
+		if n < 2 {
+			return false
+		}
+		for i := 2; i*i <= n; i++ {
+			if n%i == 0 {
+				return false
+			}
+		}
+		return true
+	}
+
+	for n := 2; ; n++ {
+		if !isPrime(n) {
+			continue
+		}
+		select {
+		case out <- n:
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Collatz generates the Collatz sequence starting from Start down to 1,
+// then closes out.
+type Collatz struct {
+	Start int
+}
+
+func (c Collatz) Run(out chan<- int, quit <-chan struct{}) {
+	defer close(out)
+
+	n := c.Start
+	if n < 1 {
+		n = 1
+	}
+	for {
+		select {
+		case out <- n:
+		case <-quit:
+			return
+		}
+		if n == 1 {
+			return
+		}
+		if n%2 == 0 {
+			n /= 2
+		} else {
+			n = 3*n + 1
+		}
+	}
+}
+
+// Arithmetic generates Start, Start+Step, Start+2*Step, ... forever.
+type Arithmetic struct {
+	Start int
+	Step  int
+}
+
+func (a Arithmetic) Run(out chan<- int, quit <-chan struct{}) {
+	n := a.Start
+	for {
+		select {
+		case out <- n:
+			n += a.Step
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Geometric generates Start, Start*Ratio, Start*Ratio^2, ... forever.
+type Geometric struct {
+	Start int
+	Ratio int
+}
+
+func (g Geometric) Run(out chan<- int, quit <-chan struct{}) {
+	n := g.Start
+	for {
+		select {
+		case out <- n:
+			n *= g.Ratio
+		case <-quit:
+			return
+		}
+	}
+}
+
+// Multiplex runs each generator in its own goroutine and merges their
+// output into a single channel of Events, each tagged with the index of the
+// generator that produced it. It uses reflect.Select so it can wait on a
+// dynamic number of generator channels at once, the same way select waits
+// on a fixed number of them. The returned channel is closed once every
+// generator has stopped, whether because it finished on its own or quit was
+// closed.
+func Multiplex(gens []Generator, quit <-chan struct{}) <-chan Event {
+	events := make(chan Event)
+
+	outs := make([]chan int, len(gens))
+	for i, g := range gens {
+		outs[i] = make(chan int)
+		go g.Run(outs[i], quit)
+	}
+
+	go func() {
+		defer close(events)
+
+		cases := make([]reflect.SelectCase, len(outs)+1)
+		for i, out := range outs {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(out)}
+		}
+		quitCase := len(outs)
+		cases[quitCase] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(quit)}
+
+		remaining := len(outs)
+		for remaining > 0 {
+			chosen, value, ok := reflect.Select(cases)
+			if chosen == quitCase {
+				return
+			}
+			if !ok {
+				// That generator finished; stop selecting on its channel.
+				cases[chosen].Chan = reflect.ValueOf((chan int)(nil))
+				remaining--
+				continue
+			}
+			select {
+			case events <- Event{Source: chosen, Value: int(value.Int())}:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return events
+}
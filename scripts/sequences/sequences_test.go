@@ -0,0 +1,69 @@
+package sequences
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMultiplexDispatchesManyGeneratorsFairly(t *testing.T) {
+	const n = 120
+	gens := make([]Generator, n)
+	for i := range gens {
+		gens[i] = Arithmetic{Start: i, Step: 1}
+	}
+
+	quit := make(chan struct{})
+	defer close(quit)
+
+	events := Multiplex(gens, quit)
+
+	seen := make(map[int]int)
+	deadline := time.After(2 * time.Second)
+	for len(seen) < n {
+		select {
+		case e := <-events:
+			seen[e.Source]++
+		case <-deadline:
+			t.Fatalf("timed out waiting for all %d generators to be dispatched, got %d", n, len(seen))
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if seen[i] == 0 {
+			t.Errorf("generator %d was never dispatched", i)
+		}
+	}
+}
+
+func TestMultiplexClosesEventsWhenQuit(t *testing.T) {
+	quit := make(chan struct{})
+	events := Multiplex([]Generator{Fibonacci{}, Primes{}}, quit)
+
+	<-events // make sure both generators have started producing
+	close(quit)
+
+	select {
+	case _, ok := <-events:
+		for ok {
+			_, ok = <-events
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("events channel was not closed after quit")
+	}
+}
+
+func TestMultiplexFinishesFiniteGenerators(t *testing.T) {
+	quit := make(chan struct{})
+	defer close(quit)
+
+	events := Multiplex([]Generator{Collatz{Start: 27}}, quit)
+
+	var last int
+	for e := range events {
+		last = e.Value
+	}
+
+	if last != 1 {
+		t.Errorf("expected Collatz sequence to end at 1, got %d", last)
+	}
+}